@@ -0,0 +1,115 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
+)
+
+// Error strings for the string interpolation patch.
+const (
+	errTemplateUnterminatedPlaceholder = "template has an unterminated ${} placeholder"
+	errFmtTemplateFieldPathNotFound    = "cannot resolve placeholder field path %q"
+)
+
+// applyStringInterpolationPatch resolves each ${path} placeholder in the
+// Patch's Template against the composite resource, substitutes '$$' for a
+// literal '$', and writes the concatenated result to ToFieldPath. It
+// mirrors applyFromCompositeFieldPatch, but combines several composite
+// fields with a format string instead of copying a single value.
+func (c *Patch) applyStringInterpolationPatch(cp, cd runtime.Object, tmp *[]interface{}) error {
+	if c.Template == nil {
+		return errors.Errorf(errFmtRequiredField, "Template", c.Type)
+	}
+
+	paved, err := fieldpath.PaveObject(cp)
+	if err != nil {
+		return err
+	}
+
+	out, err := resolveTemplate(*c.Template, paved)
+	if err != nil {
+		return err
+	}
+
+	// A ToFieldPath of the combinerIdentifierString sentinel indicates this
+	// patch is one of a set being assembled for a later Combine patch, so
+	// stash its resolved value rather than writing it out directly.
+	if c.ToFieldPath != nil && *c.ToFieldPath == combinerIdentifierString {
+		*tmp = append(*tmp, out)
+		return nil
+	}
+
+	if c.ToFieldPath != nil && *c.ToFieldPath == "" {
+		*tmp = append(*tmp, out)
+		return nil
+	}
+
+	if c.ToFieldPath == nil {
+		return errors.Errorf(errFmtRequiredField, "ToFieldPath", c.Type)
+	}
+
+	return patchFieldValueToObject(*c.ToFieldPath, out, cd, mergePolicyOf(c.Policy))
+}
+
+// resolveTemplate replaces every ${path} placeholder in tpl with the
+// stringified value found at path on paved, and every '$$' with a
+// literal '$'.
+func resolveTemplate(tpl string, paved *fieldpath.Paved) (string, error) {
+	var out strings.Builder
+
+	for i := 0; i < len(tpl); i++ {
+		if tpl[i] != '$' {
+			out.WriteByte(tpl[i])
+			continue
+		}
+
+		if i+1 >= len(tpl) {
+			return "", errors.New(errTemplateUnterminatedPlaceholder)
+		}
+
+		switch tpl[i+1] {
+		case '$':
+			out.WriteByte('$')
+			i++
+		case '{':
+			end := strings.IndexByte(tpl[i+2:], '}')
+			if end < 0 {
+				return "", errors.New(errTemplateUnterminatedPlaceholder)
+			}
+			path := tpl[i+2 : i+2+end]
+
+			v, err := paved.GetValue(path)
+			if err != nil {
+				return "", errors.Wrapf(err, errFmtTemplateFieldPathNotFound, path)
+			}
+			fmt.Fprintf(&out, "%v", v)
+
+			i += 2 + end
+		default:
+			out.WriteByte('$')
+		}
+	}
+
+	return out.String(), nil
+}
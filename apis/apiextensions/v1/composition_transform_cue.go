@@ -0,0 +1,77 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
+
+	"github.com/pkg/errors"
+)
+
+// Error strings for the CUE transform.
+const (
+	errCUENoExpression = "no CUE expression was specified"
+	errFmtCUECompile   = "cannot compile CUE expression %q"
+	errFmtCUEValidate  = "CUE expression %q did not evaluate to a concrete value"
+	errFmtCUEDecode    = "cannot decode result of CUE expression %q"
+)
+
+// cueInputIdentifier is the name the patch input is bound to within the
+// scope of a CUETransform's expression, e.g. `in & >=0 & <100`.
+const cueInputIdentifier = "in"
+
+// cueCtx is shared by every CUETransform.Resolve call. A cue.Context is
+// safe for concurrent use, and is reasonably expensive to build, so it is
+// constructed once rather than per patch per reconcile.
+var cueCtx = cuecontext.New()
+
+// A CUETransform evaluates Expression against the patch input to produce
+// an output value.
+type CUETransform struct {
+	// Expression is the CUE expression to evaluate. The patch input is
+	// bound to the identifier "in" within the expression's scope, e.g.
+	// "in & >=0 & <100".
+	Expression string `json:"expression"`
+}
+
+// Resolve runs the CUE transform, compiling and evaluating Expression
+// with the supplied input bound to "in", and returning the resulting
+// concrete value.
+func (c *CUETransform) Resolve(i interface{}) (interface{}, error) {
+	if c.Expression == "" {
+		return nil, errors.New(errCUENoExpression)
+	}
+
+	scope := cueCtx.Encode(map[string]interface{}{cueInputIdentifier: i})
+	v := cueCtx.CompileString(c.Expression, cue.Scope(scope))
+
+	if err := v.Err(); err != nil {
+		return nil, errors.Wrapf(err, errFmtCUECompile, c.Expression)
+	}
+
+	if err := v.Validate(cue.Concrete(true)); err != nil {
+		return nil, errors.Wrapf(err, errFmtCUEValidate, c.Expression)
+	}
+
+	var out interface{}
+	if err := v.Decode(&out); err != nil {
+		return nil, errors.Wrapf(err, errFmtCUEDecode, c.Expression)
+	}
+
+	return out, nil
+}
@@ -0,0 +1,838 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/pointer"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
+)
+
+// Error strings
+const (
+	errFmtInvalidPatchType            = "patch type %s is unsupported"
+	errFmtRequiredField               = "%s is required by type %s"
+	errFmtUndefinedPatchSet           = "cannot find PatchSet by name %s"
+	errFmtExtendsCycle                = "PatchSet %q extends itself, directly or transitively"
+	errFmtCombineConfigMissing        = "given combine config is missing"
+	errFmtCombineStrategyNotSupported = "combine strategy %s is not supported"
+	errFmtCombineStrategyFailed       = "%s strategy could not combine values"
+
+	errFmtMapNotFound         = "key %s is not found in map"
+	errFmtMapTypeNotSupported = "type %s is not supported for map transform"
+
+	errMathNoMultiplier   = "no math multiplier was specified"
+	errMathInputNonNumber = "input is required to be a number for a math transform"
+
+	errFmtConvertInputTypeNotSupported = "input type %s is not supported"
+	errFmtConversionPairNotSupported   = "conversion from %s to %s is not supported"
+)
+
+// combinerIdentifierString is used as a sentinel ToFieldPath on the
+// sub-patches of a PatchSet that is referenced by a Combine patch. It
+// signals applyFromCompositeFieldPatch to write its resolved value into
+// the temporary combine buffer instead of the target resource.
+const combinerIdentifierString = "combine.crossplane.io/temporary-value"
+
+// CompositionSpec specifies the desired state of the composition.
+type CompositionSpec struct {
+	// CompositeTypeRef specifies the type of composite resource that this
+	// composition is compatible with.
+	CompositeTypeRef TypeReference `json:"compositeTypeRef"`
+
+	// PatchSets define a named set of patches that may be included by any
+	// resource in this Composition, or extended by another PatchSet via
+	// Extends.
+	// +optional
+	PatchSets []PatchSet `json:"patchSets,omitempty"`
+
+	// Include is a list of PatchSetLibrary names whose PatchSets are merged
+	// into this Composition's PatchSets before inlining, making them
+	// available to be referenced by name or extended via Extends.
+	// +optional
+	Include []string `json:"include,omitempty"`
+
+	// Resources is the list of resource templates that will be used when a
+	// composite resource referring to this composition is created.
+	Resources []ComposedTemplate `json:"resources"`
+
+	// WriteConnectionSecretsToNamespace specifies the namespace in which the
+	// connection secrets of composite resource dynamically provisioned using
+	// this composition will be created.
+	// +optional
+	WriteConnectionSecretsToNamespace *string `json:"writeConnectionSecretsToNamespace,omitempty"`
+}
+
+// TypeReference is used to refer to a type for declaring compatibility.
+type TypeReference struct {
+	// APIVersion of the type.
+	APIVersion string `json:"apiVersion"`
+
+	// Kind of the type.
+	Kind string `json:"kind"`
+}
+
+// A PatchSet is a set of patches that can be reused across all resources
+// within a Composition.
+type PatchSet struct {
+	// Name of this PatchSet.
+	Name string `json:"name"`
+
+	// Extends lists the names of other PatchSets whose patches are
+	// prepended to this PatchSet's own Patches, base first, in the order
+	// given. Extended PatchSets are resolved from this Composition's own
+	// PatchSets and any merged in via CompositionSpec.Include.
+	// +optional
+	Extends []string `json:"extends,omitempty"`
+
+	// Patches will be applied as an overlay to the base resource.
+	Patches []Patch `json:"patches"`
+}
+
+// A ComposedTemplate is used to create a resource that a composite resource
+// composes.
+type ComposedTemplate struct {
+	// Name of the composed resource template, used to match against the
+	// associated composed resource in the composite resource's resource
+	// references.
+	// +optional
+	Name *string `json:"name,omitempty"`
+
+	// Base is the target resource that the patches will be applied to.
+	Base runtime.RawExtension `json:"base"`
+
+	// Patches will be applied as overlays to the base resource.
+	// +optional
+	Patches []Patch `json:"patches,omitempty"`
+
+	// ConnectionDetails lists the propagation secret keys from this target
+	// resource to the composition instance connection secret.
+	// +optional
+	ConnectionDetails []ConnectionDetail `json:"connectionDetails,omitempty"`
+}
+
+// A ConnectionDetail describes how to propagate a connection secret
+// value from a composed resource to the composite resource it is part of.
+type ConnectionDetail struct {
+	// Name of the connection secret key that will be propagated to the
+	// connection secret of the composite resource.
+	// +optional
+	Name *string `json:"name,omitempty"`
+
+	// FromConnectionSecretKey is the key that will be used to fetch the
+	// value from the given target resource's connection secret.
+	// +optional
+	FromConnectionSecretKey *string `json:"fromConnectionSecretKey,omitempty"`
+
+	// FromFieldPath is the path of the field on the composed resource whose
+	// value will be used as the connection secret value.
+	// +optional
+	FromFieldPath *string `json:"fromFieldPath,omitempty"`
+
+	// Value that will be propagated to the connection secret of the
+	// composite resource. May be set to inject a fixed, non-sensitive
+	// connection secret value.
+	// +optional
+	Value *string `json:"value,omitempty"`
+}
+
+// InlinePatchSets compiles all PatchSets in CompositionSpec into
+// corresponding Patches, and then returns any further Patches without
+// a PatchSet nested inline as detailed by the Patches' PatchSetName
+// field. This makes a Composition's DAG of PatchSets and Patches easy
+// to evaluate, because it can be treated as a simple list of Patches.
+//
+// Every supplied library whose name is listed in CompositionSpec.Include
+// has all of its PatchSets merged, in Include order, into the set of named
+// PatchSets available to this Composition before PatchSet.Extends and
+// Patch.PatchSetName references are resolved. A PatchSet merged later
+// replaces an earlier one of the same name, and the Composition's own
+// PatchSets replace any merged in from a library.
+func (c *CompositionSpec) InlinePatchSets(libraries ...PatchSetLibrary) error {
+	raw := map[string]PatchSet{}
+	for _, name := range c.Include {
+		for _, l := range libraries {
+			if l.Name != name {
+				continue
+			}
+			for _, s := range l.Spec.PatchSets {
+				raw[s.Name] = s
+			}
+		}
+	}
+	for _, s := range c.PatchSets {
+		raw[s.Name] = s
+	}
+
+	pn := map[string][]Patch{}
+	for name := range raw {
+		ps, err := resolveExtends(name, raw, map[string]bool{})
+		if err != nil {
+			return err
+		}
+		pn[name] = ps
+	}
+
+	for i, r := range c.Resources {
+		var po []Patch
+		for _, p := range r.Patches {
+			if p.Type != PatchTypePatchSet {
+				po = append(po, p)
+				continue
+			}
+
+			if p.PatchSetName == nil {
+				continue
+			}
+
+			ps, ok := pn[*p.PatchSetName]
+			if !ok {
+				return errors.Errorf(errFmtUndefinedPatchSet, *p.PatchSetName)
+			}
+
+			if p.Combine.Type == "" {
+				po = append(po, ps...)
+				continue
+			}
+
+			// When a PatchSet reference configures a Combine, every patch
+			// that composes the set is rewired to feed the combine buffer
+			// rather than writing directly to the composed resource, and
+			// the original combining patch is appended last so it can
+			// consume the buffered values.
+			for _, sp := range ps {
+				sp.ToFieldPath = pointer.StringPtr(combinerIdentifierString)
+				po = append(po, sp)
+			}
+			po = append(po, p)
+		}
+		c.Resources[i].Patches = po
+	}
+
+	return nil
+}
+
+// resolveExtends flattens the named PatchSet's Patches, prepending the
+// flattened Patches of everything it Extends (base first, in the order
+// given), then detects and rejects cycles using visiting, the set of
+// PatchSet names currently being resolved on the calling stack.
+func resolveExtends(name string, raw map[string]PatchSet, visiting map[string]bool) ([]Patch, error) {
+	s, ok := raw[name]
+	if !ok {
+		return nil, errors.Errorf(errFmtUndefinedPatchSet, name)
+	}
+
+	if visiting[name] {
+		return nil, errors.Errorf(errFmtExtendsCycle, name)
+	}
+	visiting[name] = true
+	defer delete(visiting, name)
+
+	var out []Patch
+	for _, e := range s.Extends {
+		ps, err := resolveExtends(e, raw, visiting)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, ps...)
+	}
+
+	return append(out, s.Patches...), nil
+}
+
+// A PatchType is a type of patch.
+type PatchType string
+
+// Patch types.
+const (
+	PatchTypeFromCompositeFieldPath PatchType = "FromCompositeFieldPath"
+	PatchTypePatchSet               PatchType = "PatchSet"
+	PatchTypeStringInterpolation    PatchType = "StringInterpolation"
+	PatchTypeToCompositeFieldPath   PatchType = "ToCompositeFieldPath"
+	PatchTypeCombineToComposite     PatchType = "CombineToComposite"
+)
+
+// A Patch is used to patch the value of a field on a composed resource,
+// taking the value from a field on the composite resource to which it is
+// composed, or, for Type ToCompositeFieldPath and CombineToComposite, to
+// patch the value of a field on the composite resource.
+type Patch struct {
+	// Type sets the patching behaviour to be used. Default is
+	// FromCompositeFieldPath.
+	// +optional
+	// +kubebuilder:validation:Enum=FromCompositeFieldPath;PatchSet;StringInterpolation;ToCompositeFieldPath;CombineToComposite
+	// +kubebuilder:default=FromCompositeFieldPath
+	Type PatchType `json:"type,omitempty"`
+
+	// FromFieldPath is the path of the field on the resource whose value is
+	// to be used as input. For Type ToCompositeFieldPath this is a path on
+	// the composed resource; for FromCompositeFieldPath it is a path on the
+	// composite resource. Other types do not read it.
+	// +optional
+	FromFieldPath *string `json:"fromFieldPath,omitempty"`
+
+	// Policy configures the specifics of patching behaviour.
+	// +optional
+	Policy *PatchPolicy `json:"policy,omitempty"`
+
+	// Template is a string containing ${metadata.labels.foo} style
+	// placeholders, each resolved against the composite resource and
+	// substituted in to produce the value written to ToFieldPath. Use
+	// '$$' to escape a literal '$'. Required when Type is
+	// StringInterpolation.
+	// +optional
+	Template *string `json:"template,omitempty"`
+
+	// ToFieldPath is the path of the field on the resource whose value will
+	// be changed with the result of transforms. Leave unset if you'd like to
+	// propagate to the same path as FromFieldPath. Set to the empty string
+	// to have the resolved value stashed for a later combining patch instead
+	// of written to a resource.
+	// +optional
+	ToFieldPath *string `json:"toFieldPath,omitempty"`
+
+	// PatchSetName to include patches from. Required when Type is
+	// PatchSet.
+	// +optional
+	PatchSetName *string `json:"patchSetName,omitempty"`
+
+	// Combine is the set of patches whose results will be combined before
+	// being transformed and applied to the ToFieldPath. Only used when
+	// referencing a PatchSet whose patches should be merged, or when Type
+	// is CombineToComposite.
+	// +optional
+	Combine Combine `json:"combine,omitempty"`
+
+	// Transforms are the list of functions that are used to transform the
+	// input before it is applied to the resource field.
+	// +optional
+	Transforms []Transform `json:"transforms,omitempty"`
+}
+
+// A CombineType is a type of combine strategy.
+type CombineType string
+
+// Combine strategies.
+const (
+	CombineTypeString CombineType = "string"
+)
+
+// Combine configures how to combine multiple temporary patch values
+// produced by a PatchSet reference into a single value.
+type Combine struct {
+	// Type of the combine strategy to use.
+	// +optional
+	// +kubebuilder:validation:Enum=string
+	Type CombineType `json:"type,omitempty"`
+
+	// String declares that the input values should be combined into a
+	// single string using the relevant settings for formatting purposes.
+	// +optional
+	String *StringCombine `json:"string,omitempty"`
+}
+
+// StringCombine combines multiple input values into a single string.
+type StringCombine struct {
+	// Format the input values will be substituted into. The order of
+	// substitution matches the order in which the Combine's patches
+	// produced their values.
+	Format string `json:"fmt"`
+}
+
+// A FromFieldPathPolicy determines whether a patch whose source field path
+// is absent on the source resource is required to be resolvable.
+type FromFieldPathPolicy string
+
+// FromFieldPath policies.
+const (
+	FromFieldPathPolicyOptional FromFieldPathPolicy = "Optional"
+	FromFieldPathPolicyRequired FromFieldPathPolicy = "Required"
+)
+
+// A MergePolicy determines how a patch's resolved value is written to a
+// field path that is already populated on the target resource.
+type MergePolicy string
+
+// Merge policies.
+const (
+	// MergePolicyReplace overwrites the target field path with the
+	// resolved value. This is the default.
+	MergePolicyReplace MergePolicy = "Replace"
+
+	// MergePolicyAppend appends the resolved value to the target field
+	// path's existing slice. Only applies when both are slices.
+	MergePolicyAppend MergePolicy = "Append"
+
+	// MergePolicyMergeObjects merges the resolved value into the target
+	// field path's existing map, with the resolved value's keys taking
+	// precedence on conflict. Only applies when both are objects.
+	MergePolicyMergeObjects MergePolicy = "MergeObjects"
+)
+
+// A PatchPolicy configures the specifics of patching behaviour.
+type PatchPolicy struct {
+	// FromFieldPath specifies whether the FromFieldPath must be resolvable,
+	// i.e. whether the patch is allowed to silently no-op when the source
+	// field is absent. Defaults to Required.
+	// +optional
+	// +kubebuilder:validation:Enum=Optional;Required
+	FromFieldPath *FromFieldPathPolicy `json:"fromFieldPath,omitempty"`
+
+	// MergePolicy specifies how the patch's resolved value is combined with
+	// a value already present at ToFieldPath. Defaults to Replace.
+	// +optional
+	// +kubebuilder:validation:Enum=Replace;Append;MergeObjects
+	MergePolicy *MergePolicy `json:"mergePolicy,omitempty"`
+}
+
+// fromFieldPathRequired returns true unless p explicitly opts the patch's
+// FromFieldPath into FromFieldPathPolicyOptional.
+func fromFieldPathRequired(p *PatchPolicy) bool {
+	if p == nil || p.FromFieldPath == nil {
+		return true
+	}
+	return *p.FromFieldPath == FromFieldPathPolicyRequired
+}
+
+// mergePolicyOf returns the MergePolicy configured by p, or nil (i.e.
+// MergePolicyReplace) if p does not configure one.
+func mergePolicyOf(p *PatchPolicy) *MergePolicy {
+	if p == nil {
+		return nil
+	}
+	return p.MergePolicy
+}
+
+// Apply resolves the patch, transforms the value if necessary, and applies
+// it to the composed resource, or (when ToFieldPath is the empty string, or
+// when this Patch participates in a Combine) stashes the resolved value in
+// tmp for a later combining patch to consume.
+func (c *Patch) Apply(cp, cd runtime.Object, tmp *[]interface{}) error {
+	switch c.Type {
+	case PatchTypePatchSet:
+		return c.applyCombine(cd, tmp)
+	case PatchTypeCombineToComposite:
+		return c.applyCombine(cp, tmp)
+	case PatchTypeFromCompositeFieldPath:
+		return c.applyFromCompositeFieldPatch(cp, cd, tmp)
+	case PatchTypeToCompositeFieldPath:
+		return c.applyToCompositeFieldPatch(cp, cd, tmp)
+	case PatchTypeStringInterpolation:
+		return c.applyStringInterpolationPatch(cp, cd, tmp)
+	default:
+		return errors.Errorf(errFmtInvalidPatchType, c.Type)
+	}
+}
+
+// applyCombine consumes the values buffered by a set of patches that fed
+// this Combine and writes the combined result to ToFieldPath on dst. Used
+// by a Patch of type PatchTypePatchSet (dst is the composed resource) or
+// PatchTypeCombineToComposite (dst is the composite resource).
+func (c *Patch) applyCombine(dst runtime.Object, tmp *[]interface{}) error {
+	if c.Combine.Type == "" {
+		// A PatchTypePatchSet reference with no Combine configured is a
+		// plain, non-combining reference, and is a no-op here because its
+		// patches were already inlined directly into the resource's Patches
+		// by InlinePatchSets. PatchTypeCombineToComposite has no other
+		// meaning, so the same absence here is always a configuration
+		// mistake.
+		if c.Type == PatchTypeCombineToComposite {
+			return errors.New(errFmtCombineConfigMissing)
+		}
+		return nil
+	}
+
+	if c.ToFieldPath == nil {
+		return errors.Errorf(errFmtRequiredField, "ToFieldPath", c.Type)
+	}
+
+	switch c.Combine.Type {
+	case CombineTypeString:
+		if c.Combine.String == nil {
+			return errors.New(errFmtCombineConfigMissing)
+		}
+
+		args := make([]interface{}, len(*tmp))
+		copy(args, *tmp)
+		out := fmt.Sprintf(c.Combine.String.Format, args...)
+		*tmp = (*tmp)[:0]
+
+		return patchFieldValueToObject(*c.ToFieldPath, out, dst, mergePolicyOf(c.Policy))
+	default:
+		return errors.Errorf(errFmtCombineStrategyNotSupported, string(c.Combine.Type))
+	}
+}
+
+// applyFromCompositeFieldPatch patches the composed resource cd, using a
+// source field on the composite resource cp. Values may be transformed if
+// desired.
+func (c *Patch) applyFromCompositeFieldPatch(cp, cd runtime.Object, tmp *[]interface{}) error {
+	if c.FromFieldPath == nil {
+		return errors.Errorf(errFmtRequiredField, "FromFieldPath", c.Type)
+	}
+
+	paved, err := fieldpath.PaveObject(cp)
+	if err != nil {
+		return err
+	}
+
+	in, err := paved.GetValue(*c.FromFieldPath)
+	if err != nil {
+		if fieldpath.IsNotFound(err) && !fromFieldPathRequired(c.Policy) {
+			return nil
+		}
+		return err
+	}
+
+	out := in
+	for i, t := range c.Transforms {
+		if out, err = t.Resolve(out); err != nil {
+			return errors.Wrapf(err, "transform at index %d returned error", i)
+		}
+	}
+
+	// A ToFieldPath of the combinerIdentifierString sentinel indicates this
+	// patch is one of a set being assembled for a later Combine patch, so
+	// stash its resolved value rather than writing it out directly.
+	if c.ToFieldPath != nil && *c.ToFieldPath == combinerIdentifierString {
+		*tmp = append(*tmp, out)
+		return nil
+	}
+
+	// A ToFieldPath of empty string requests the resolved value be
+	// returned via tmp rather than applied to the target resource.
+	if c.ToFieldPath != nil && *c.ToFieldPath == "" {
+		*tmp = append(*tmp, out)
+		return nil
+	}
+
+	path := *c.FromFieldPath
+	if c.ToFieldPath != nil {
+		path = *c.ToFieldPath
+	}
+
+	return patchFieldValueToObject(path, out, cd, mergePolicyOf(c.Policy))
+}
+
+// applyToCompositeFieldPatch patches the composite resource cp, using a
+// source field on the composed resource cd. This is the inverse of
+// applyFromCompositeFieldPatch, and is used to propagate observed state
+// (e.g. status.atProvider.endpoint) from a composed resource back up to
+// the composite.
+func (c *Patch) applyToCompositeFieldPatch(cp, cd runtime.Object, tmp *[]interface{}) error {
+	if c.FromFieldPath == nil {
+		return errors.Errorf(errFmtRequiredField, "FromFieldPath", c.Type)
+	}
+
+	paved, err := fieldpath.PaveObject(cd)
+	if err != nil {
+		return err
+	}
+
+	in, err := paved.GetValue(*c.FromFieldPath)
+	if err != nil {
+		if fieldpath.IsNotFound(err) && !fromFieldPathRequired(c.Policy) {
+			return nil
+		}
+		return err
+	}
+
+	out := in
+	for i, t := range c.Transforms {
+		if out, err = t.Resolve(out); err != nil {
+			return errors.Wrapf(err, "transform at index %d returned error", i)
+		}
+	}
+
+	if c.ToFieldPath != nil && *c.ToFieldPath == combinerIdentifierString {
+		*tmp = append(*tmp, out)
+		return nil
+	}
+
+	if c.ToFieldPath != nil && *c.ToFieldPath == "" {
+		*tmp = append(*tmp, out)
+		return nil
+	}
+
+	path := *c.FromFieldPath
+	if c.ToFieldPath != nil {
+		path = *c.ToFieldPath
+	}
+
+	return patchFieldValueToObject(path, out, cp, mergePolicyOf(c.Policy))
+}
+
+// patchFieldValueToObject applies the value to the "to" resource, using
+// the field path that is provided. If policy is non-nil and not
+// MergePolicyReplace, and a value already exists at path, the two values
+// are combined per the policy instead of value replacing it outright;
+// this only applies when both values are of a compatible kind (slices for
+// Append, objects for MergeObjects), otherwise value simply replaces it.
+func patchFieldValueToObject(path string, value interface{}, to runtime.Object, policy *MergePolicy) error {
+	paved, err := fieldpath.PaveObject(to)
+	if err != nil {
+		return err
+	}
+
+	value = mergeFieldValue(paved, path, value, policy)
+
+	if err := paved.SetValue(path, value); err != nil {
+		return err
+	}
+
+	return runtime.DefaultUnstructuredConverter.FromUnstructured(paved.UnstructuredContent(), to)
+}
+
+// mergeFieldValue combines value with whatever is already present at path
+// on paved, according to policy. It returns value unchanged if policy is
+// nil, MergePolicyReplace, there is nothing at path yet, or the existing
+// and new values aren't both of the kind the policy expects.
+func mergeFieldValue(paved *fieldpath.Paved, path string, value interface{}, policy *MergePolicy) interface{} {
+	if policy == nil {
+		return value
+	}
+
+	existing, err := paved.GetValue(path)
+	if err != nil {
+		return value
+	}
+
+	switch *policy {
+	case MergePolicyAppend:
+		es, ok := existing.([]interface{})
+		vs, ok2 := value.([]interface{})
+		if ok && ok2 {
+			return append(es, vs...)
+		}
+	case MergePolicyMergeObjects:
+		em, ok := existing.(map[string]interface{})
+		vm, ok2 := value.(map[string]interface{})
+		if ok && ok2 {
+			merged := make(map[string]interface{}, len(em)+len(vm))
+			for k, v := range em {
+				merged[k] = v
+			}
+			for k, v := range vm {
+				merged[k] = v
+			}
+			return merged
+		}
+	}
+
+	return value
+}
+
+// A TransformType is a type of transform.
+type TransformType string
+
+// Transform types.
+const (
+	TransformTypeMap     TransformType = "map"
+	TransformTypeMath    TransformType = "math"
+	TransformTypeString  TransformType = "string"
+	TransformTypeConvert TransformType = "convert"
+	TransformTypeCUE     TransformType = "cue"
+)
+
+// Transform is a unit of process whose input is transformed into an output
+// with the supplied configuration.
+type Transform struct {
+	// Type of the transform to be run.
+	// +kubebuilder:validation:Enum=map;math;string;convert;cue
+	Type TransformType `json:"type"`
+
+	// Math is used to transform the input via mathematical operations such
+	// as multiplication.
+	// +optional
+	Math *MathTransform `json:"math,omitempty"`
+
+	// Map uses the input as a key in the given map and returns the value.
+	// +optional
+	Map *MapTransform `json:"map,omitempty"`
+
+	// String is used to transform the input into a string, or a different
+	// kind of string (e.g. base64).
+	// +optional
+	String *StringTransform `json:"string,omitempty"`
+
+	// Convert is used to cast the input into the given output type.
+	// +optional
+	Convert *ConvertTransform `json:"convert,omitempty"`
+
+	// CUE evaluates a CUE expression against the input to produce the
+	// output value.
+	// +optional
+	CUE *CUETransform `json:"cue,omitempty"`
+}
+
+// Resolve runs the Transform against the supplied input, and dispatches to
+// the Resolve method of the configured transform type.
+func (t *Transform) Resolve(input interface{}) (interface{}, error) {
+	switch t.Type {
+	case TransformTypeMath:
+		if t.Math == nil {
+			return nil, errors.Errorf(errFmtRequiredField, "Math", t.Type)
+		}
+		return t.Math.Resolve(input)
+	case TransformTypeMap:
+		if t.Map == nil {
+			return nil, errors.Errorf(errFmtRequiredField, "Map", t.Type)
+		}
+		return t.Map.Resolve(input)
+	case TransformTypeString:
+		if t.String == nil {
+			return nil, errors.Errorf(errFmtRequiredField, "String", t.Type)
+		}
+		return t.String.Resolve(input)
+	case TransformTypeConvert:
+		if t.Convert == nil {
+			return nil, errors.Errorf(errFmtRequiredField, "Convert", t.Type)
+		}
+		return t.Convert.Resolve(input)
+	case TransformTypeCUE:
+		if t.CUE == nil {
+			return nil, errors.Errorf(errFmtRequiredField, "CUE", t.Type)
+		}
+		return t.CUE.Resolve(input)
+	default:
+		return nil, errors.Errorf(errFmtInvalidPatchType, t.Type)
+	}
+}
+
+// MathTransform conducts mathematical operations on the input with the
+// given configuration.
+type MathTransform struct {
+	// Multiply the value.
+	// +optional
+	Multiply *int64 `json:"multiply,omitempty"`
+}
+
+// Resolve runs the Math transform.
+func (m *MathTransform) Resolve(input interface{}) (interface{}, error) {
+	if m.Multiply == nil {
+		return nil, errors.New(errMathNoMultiplier)
+	}
+
+	switch i := input.(type) {
+	case int64:
+		return i * (*m.Multiply), nil
+	case int:
+		return int64(i) * (*m.Multiply), nil
+	default:
+		return nil, errors.New(errMathInputNonNumber)
+	}
+}
+
+// MapTransform returns a value for the input from the given map.
+type MapTransform struct {
+	// Pairs is the map that will be used for transform.
+	Pairs map[string]string `json:"pairs"`
+}
+
+// Resolve runs the Map transform.
+func (m *MapTransform) Resolve(input interface{}) (interface{}, error) {
+	switch i := input.(type) {
+	case string:
+		v, ok := m.Pairs[i]
+		if !ok {
+			return nil, errors.Errorf(errFmtMapNotFound, i)
+		}
+		return v, nil
+	default:
+		return nil, errors.Errorf(errFmtMapTypeNotSupported, reflect.TypeOf(input).Kind().String())
+	}
+}
+
+// StringTransform formats the input using the given format string.
+type StringTransform struct {
+	// Format the input using a Go format string. See
+	// https://golang.org/pkg/fmt/ for details.
+	Format string `json:"fmt"`
+}
+
+// Resolve runs the String transform.
+func (s *StringTransform) Resolve(input interface{}) (interface{}, error) {
+	return fmt.Sprintf(s.Format, input), nil
+}
+
+// ConvertTransform type strings.
+const (
+	ConvertTransformTypeString  = "string"
+	ConvertTransformTypeInt     = "int"
+	ConvertTransformTypeInt64   = "int64"
+	ConvertTransformTypeBool    = "bool"
+	ConvertTransformTypeFloat64 = "float64"
+)
+
+// ConvertTransform converts the input into a new object whose type is
+// supplied.
+type ConvertTransform struct {
+	// ToType is the type of the output of this transform.
+	// +kubebuilder:validation:Enum=string;int;int64;bool;float64
+	ToType string `json:"toType"`
+}
+
+// Resolve runs the Convert transform.
+func (c *ConvertTransform) Resolve(input interface{}) (interface{}, error) {
+	switch i := input.(type) {
+	case string:
+		switch c.ToType {
+		case ConvertTransformTypeBool:
+			b, err := strconv.ParseBool(i)
+			return b, errors.Wrapf(err, errFmtConversionPairNotSupported, "string", c.ToType)
+		case ConvertTransformTypeInt, ConvertTransformTypeInt64:
+			n, err := strconv.ParseInt(i, 10, 64)
+			return n, errors.Wrapf(err, errFmtConversionPairNotSupported, "string", c.ToType)
+		case ConvertTransformTypeFloat64:
+			f, err := strconv.ParseFloat(i, 64)
+			return f, errors.Wrapf(err, errFmtConversionPairNotSupported, "string", c.ToType)
+		case ConvertTransformTypeString:
+			return i, nil
+		default:
+			return nil, errors.Errorf(errFmtConversionPairNotSupported, "string", c.ToType)
+		}
+	default:
+		return nil, errors.Errorf(errFmtConvertInputTypeNotSupported, reflect.TypeOf(input).Kind().String())
+	}
+}
+
+// A Composition defines a collection of managed resources or functions that
+// crossplane uses to create a complex custom resource for use by consumers.
+//
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster,categories=crossplane
+// +kubebuilder:subresource:status
+type Composition struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   CompositionSpec   `json:"spec"`
+	Status CompositionStatus `json:"status,omitempty"`
+}
+
+// CompositionStatus shows the observed state of the composition.
+type CompositionStatus struct {
+	xpv1.ConditionedStatus `json:",inline"`
+}
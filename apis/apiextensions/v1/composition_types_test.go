@@ -18,22 +18,26 @@ package v1
 
 import (
 	"reflect"
+	"sort"
 	"testing"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/pkg/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"k8s.io/utils/pointer"
 
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
 	"github.com/crossplane/crossplane-runtime/pkg/resource/fake"
 	"github.com/crossplane/crossplane-runtime/pkg/test"
 )
 
 func TestPatchTypeReplacement(t *testing.T) {
 	type args struct {
-		comp CompositionSpec
+		comp      CompositionSpec
+		libraries []PatchSetLibrary
 	}
 
 	type want struct {
@@ -317,11 +321,116 @@ func TestPatchTypeReplacement(t *testing.T) {
 				},
 			},
 		},
+		"ExtendsDiamond": {
+			reason: "Should flatten a diamond of Extends base-first, in the order given, duplicating the shared base for each branch that extends it",
+			args: args{
+				comp: CompositionSpec{
+					PatchSets: []PatchSet{
+						{
+							Name: "base",
+							Patches: []Patch{{
+								Type:          PatchTypeFromCompositeFieldPath,
+								FromFieldPath: pointer.StringPtr("spec.base"),
+							}},
+						},
+						{
+							Name:    "left",
+							Extends: []string{"base"},
+							Patches: []Patch{{
+								Type:          PatchTypeFromCompositeFieldPath,
+								FromFieldPath: pointer.StringPtr("spec.left"),
+							}},
+						},
+						{
+							Name:    "right",
+							Extends: []string{"base"},
+							Patches: []Patch{{
+								Type:          PatchTypeFromCompositeFieldPath,
+								FromFieldPath: pointer.StringPtr("spec.right"),
+							}},
+						},
+						{
+							Name:    "top",
+							Extends: []string{"left", "right"},
+							Patches: []Patch{{
+								Type:          PatchTypeFromCompositeFieldPath,
+								FromFieldPath: pointer.StringPtr("spec.top"),
+							}},
+						},
+					},
+					Resources: []ComposedTemplate{{
+						Patches: []Patch{{
+							Type:         PatchTypePatchSet,
+							PatchSetName: pointer.StringPtr("top"),
+						}},
+					}},
+				},
+			},
+			want: want{
+				resources: []ComposedTemplate{{
+					Patches: []Patch{
+						{Type: PatchTypeFromCompositeFieldPath, FromFieldPath: pointer.StringPtr("spec.base")},
+						{Type: PatchTypeFromCompositeFieldPath, FromFieldPath: pointer.StringPtr("spec.left")},
+						{Type: PatchTypeFromCompositeFieldPath, FromFieldPath: pointer.StringPtr("spec.base")},
+						{Type: PatchTypeFromCompositeFieldPath, FromFieldPath: pointer.StringPtr("spec.right")},
+						{Type: PatchTypeFromCompositeFieldPath, FromFieldPath: pointer.StringPtr("spec.top")},
+					},
+				}},
+				err: nil,
+			},
+		},
+		"LocalPatchSetOverridesInclude": {
+			reason: "A PatchSet defined locally on the Composition should take precedence over one of the same name merged in via Include",
+			args: args{
+				libraries: []PatchSetLibrary{
+					{
+						ObjectMeta: metav1.ObjectMeta{Name: "lib"},
+						Spec: PatchSetLibrarySpec{
+							PatchSets: []PatchSet{
+								{
+									Name: "common",
+									Patches: []Patch{{
+										Type:          PatchTypeFromCompositeFieldPath,
+										FromFieldPath: pointer.StringPtr("spec.fromLibrary"),
+									}},
+								},
+							},
+						},
+					},
+				},
+				comp: CompositionSpec{
+					Include: []string{"lib"},
+					PatchSets: []PatchSet{
+						{
+							Name: "common",
+							Patches: []Patch{{
+								Type:          PatchTypeFromCompositeFieldPath,
+								FromFieldPath: pointer.StringPtr("spec.fromLocal"),
+							}},
+						},
+					},
+					Resources: []ComposedTemplate{{
+						Patches: []Patch{{
+							Type:         PatchTypePatchSet,
+							PatchSetName: pointer.StringPtr("common"),
+						}},
+					}},
+				},
+			},
+			want: want{
+				resources: []ComposedTemplate{{
+					Patches: []Patch{
+						{Type: PatchTypeFromCompositeFieldPath, FromFieldPath: pointer.StringPtr("spec.fromLocal")},
+					},
+				}},
+				err: nil,
+			},
+		},
 	}
 
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
-			err := tc.args.comp.InlinePatchSets()
+			err := tc.args.comp.InlinePatchSets(tc.args.libraries...)
 
 			if diff := cmp.Diff(tc.want.resources, tc.args.comp.Resources); diff != "" {
 				t.Errorf("\n%s\nInlinePatchSets(b): -want, +got:\n%s", tc.reason, diff)
@@ -333,6 +442,132 @@ func TestPatchTypeReplacement(t *testing.T) {
 	}
 }
 
+func TestResolveExtends(t *testing.T) {
+	type args struct {
+		name string
+		raw  map[string]PatchSet
+	}
+
+	type want struct {
+		patches []Patch
+		err     error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args
+		want
+	}{
+		"Self": {
+			reason: "Should return an error when a PatchSet extends itself",
+			args: args{
+				name: "a",
+				raw: map[string]PatchSet{
+					"a": {
+						Name:    "a",
+						Extends: []string{"a"},
+						Patches: []Patch{{
+							Type:          PatchTypeFromCompositeFieldPath,
+							FromFieldPath: pointer.StringPtr("spec.a"),
+						}},
+					},
+				},
+			},
+			want: want{
+				err: errors.Errorf(errFmtExtendsCycle, "a"),
+			},
+		},
+		"TwoNodeCycle": {
+			reason: "Should return an error naming the PatchSet that closes the cycle when two PatchSets extend one another",
+			args: args{
+				name: "a",
+				raw: map[string]PatchSet{
+					"a": {
+						Name:    "a",
+						Extends: []string{"b"},
+						Patches: []Patch{{
+							Type:          PatchTypeFromCompositeFieldPath,
+							FromFieldPath: pointer.StringPtr("spec.a"),
+						}},
+					},
+					"b": {
+						Name:    "b",
+						Extends: []string{"a"},
+						Patches: []Patch{{
+							Type:          PatchTypeFromCompositeFieldPath,
+							FromFieldPath: pointer.StringPtr("spec.b"),
+						}},
+					},
+				},
+			},
+			want: want{
+				err: errors.Errorf(errFmtExtendsCycle, "a"),
+			},
+		},
+		"Diamond": {
+			reason: "Should flatten a diamond of Extends base-first, in the order given, duplicating the shared base for each branch that extends it",
+			args: args{
+				name: "top",
+				raw: map[string]PatchSet{
+					"base": {
+						Name: "base",
+						Patches: []Patch{{
+							Type:          PatchTypeFromCompositeFieldPath,
+							FromFieldPath: pointer.StringPtr("spec.base"),
+						}},
+					},
+					"left": {
+						Name:    "left",
+						Extends: []string{"base"},
+						Patches: []Patch{{
+							Type:          PatchTypeFromCompositeFieldPath,
+							FromFieldPath: pointer.StringPtr("spec.left"),
+						}},
+					},
+					"right": {
+						Name:    "right",
+						Extends: []string{"base"},
+						Patches: []Patch{{
+							Type:          PatchTypeFromCompositeFieldPath,
+							FromFieldPath: pointer.StringPtr("spec.right"),
+						}},
+					},
+					"top": {
+						Name:    "top",
+						Extends: []string{"left", "right"},
+						Patches: []Patch{{
+							Type:          PatchTypeFromCompositeFieldPath,
+							FromFieldPath: pointer.StringPtr("spec.top"),
+						}},
+					},
+				},
+			},
+			want: want{
+				patches: []Patch{
+					{Type: PatchTypeFromCompositeFieldPath, FromFieldPath: pointer.StringPtr("spec.base")},
+					{Type: PatchTypeFromCompositeFieldPath, FromFieldPath: pointer.StringPtr("spec.left")},
+					{Type: PatchTypeFromCompositeFieldPath, FromFieldPath: pointer.StringPtr("spec.base")},
+					{Type: PatchTypeFromCompositeFieldPath, FromFieldPath: pointer.StringPtr("spec.right")},
+					{Type: PatchTypeFromCompositeFieldPath, FromFieldPath: pointer.StringPtr("spec.top")},
+				},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := resolveExtends(tc.args.name, tc.args.raw, map[string]bool{})
+
+			if diff := cmp.Diff(tc.want.patches, got); diff != "" {
+				t.Errorf("\n%s\nresolveExtends(...): -want, +got:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nresolveExtends(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
 func TestMapResolve(t *testing.T) {
 	type args struct {
 		m map[string]string
@@ -557,12 +792,91 @@ func TestConvertResolve(t *testing.T) {
 	}
 }
 
+func TestCUEResolve(t *testing.T) {
+	type args struct {
+		expression string
+		i          interface{}
+	}
+	type want struct {
+		o         interface{}
+		err       error
+		wantError bool
+	}
+
+	cases := map[string]struct {
+		reason string
+		args
+		want
+	}{
+		"NoExpression": {
+			reason: "Should return an error if no Expression is configured",
+			args:   args{},
+			want: want{
+				err: errors.New(errCUENoExpression),
+			},
+		},
+		"CompileError": {
+			reason: "Should return an error if Expression does not compile",
+			args: args{
+				expression: "in +",
+				i:          1,
+			},
+			want: want{
+				wantError: true,
+			},
+		},
+		"NonConcreteRejected": {
+			reason: "Should return an error if Expression evaluates to a non-concrete value, e.g. a bare type",
+			args: args{
+				expression: "int",
+				i:          1,
+			},
+			want: want{
+				wantError: true,
+			},
+		},
+		"Identity": {
+			reason: "Should return the input unchanged when Expression is just the input identifier",
+			args: args{
+				expression: "in",
+				i:          "hello",
+			},
+			want: want{
+				o: "hello",
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := (&CUETransform{Expression: tc.expression}).Resolve(tc.i)
+
+			if tc.want.wantError {
+				if err == nil {
+					t.Errorf("\n%s\nResolve(...): wanted an error, got none", tc.reason)
+				}
+				return
+			}
+
+			if diff := cmp.Diff(tc.want.o, got); diff != "" {
+				t.Errorf("\n%s\nResolve(...): -want, +got:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nResolve(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
 func TestPatchApply(t *testing.T) {
 	now := metav1.NewTime(time.Unix(0, 0))
 	lpt := fake.ConnectionDetailsLastPublishedTimer{
 		Time: &now,
 	}
 
+	optional := FromFieldPathPolicyOptional
+	mergeAppend := MergePolicyAppend
+	mergeObjects := MergePolicyMergeObjects
+
 	type args struct {
 		patch Patch
 		cp    *fake.Composite
@@ -748,6 +1062,216 @@ func TestPatchApply(t *testing.T) {
 				},
 			},
 		},
+		"ValidToCompositeFieldPathPatch": {
+			reason: "Should correctly apply a ToCompositeFieldPath patch from the composed resource to the composite",
+			args: args{
+				patch: Patch{
+					Type:          PatchTypeToCompositeFieldPath,
+					FromFieldPath: pointer.StringPtr("objectMeta.labels"),
+					ToFieldPath:   pointer.StringPtr("objectMeta.labels"),
+				},
+				cp: &fake.Composite{
+					ConnectionDetailsLastPublishedTimer: lpt,
+				},
+				cd: &fake.Composed{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "cd",
+						Labels: map[string]string{
+							"Test": "blah",
+						},
+					},
+				},
+			},
+			want: want{
+				cp: &fake.Composite{
+					ObjectMeta: metav1.ObjectMeta{
+						Labels: map[string]string{
+							"Test": "blah",
+						},
+					},
+					ConnectionDetailsLastPublishedTimer: lpt,
+				},
+				err: nil,
+			},
+		},
+		"ToCompositeFieldPathMissingOptional": {
+			reason: "Should return no error when FromFieldPath is absent and the policy marks it Optional",
+			args: args{
+				patch: Patch{
+					Type:          PatchTypeToCompositeFieldPath,
+					FromFieldPath: pointer.StringPtr("status.atProvider.missing"),
+					ToFieldPath:   pointer.StringPtr("status.missing"),
+					Policy: &PatchPolicy{
+						FromFieldPath: &optional,
+					},
+				},
+				cp: &fake.Composite{
+					ConnectionDetailsLastPublishedTimer: lpt,
+				},
+				cd: &fake.Composed{ObjectMeta: metav1.ObjectMeta{Name: "cd"}},
+			},
+			want: want{
+				cp: &fake.Composite{
+					ConnectionDetailsLastPublishedTimer: lpt,
+				},
+				err: nil,
+			},
+		},
+		"MergePolicyAppendSlice": {
+			reason: "Should append the patch value to an existing slice at ToFieldPath when MergePolicy is Append",
+			args: args{
+				patch: Patch{
+					Type:          PatchTypeFromCompositeFieldPath,
+					FromFieldPath: pointer.StringPtr("objectMeta.finalizers"),
+					ToFieldPath:   pointer.StringPtr("objectMeta.finalizers"),
+					Policy: &PatchPolicy{
+						MergePolicy: &mergeAppend,
+					},
+				},
+				cp: &fake.Composite{
+					ObjectMeta: metav1.ObjectMeta{
+						Finalizers: []string{"new"},
+					},
+					ConnectionDetailsLastPublishedTimer: lpt,
+				},
+				cd: &fake.Composed{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:       "cd",
+						Finalizers: []string{"existing"},
+					},
+				},
+			},
+			want: want{
+				cd: &fake.Composed{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:       "cd",
+						Finalizers: []string{"existing", "new"},
+					},
+				},
+				err: nil,
+			},
+		},
+		"MergePolicyMergeObjectsMap": {
+			reason: "Should merge the patch value into an existing map at ToFieldPath when MergePolicy is MergeObjects",
+			args: args{
+				patch: Patch{
+					Type:          PatchTypeFromCompositeFieldPath,
+					FromFieldPath: pointer.StringPtr("objectMeta.labels"),
+					ToFieldPath:   pointer.StringPtr("objectMeta.labels"),
+					Policy: &PatchPolicy{
+						MergePolicy: &mergeObjects,
+					},
+				},
+				cp: &fake.Composite{
+					ObjectMeta: metav1.ObjectMeta{
+						Labels: map[string]string{
+							"New": "value",
+						},
+					},
+					ConnectionDetailsLastPublishedTimer: lpt,
+				},
+				cd: &fake.Composed{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "cd",
+						Labels: map[string]string{
+							"Existing": "value",
+						},
+					},
+				},
+			},
+			want: want{
+				cd: &fake.Composed{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "cd",
+						Labels: map[string]string{
+							"Existing": "value",
+							"New":      "value",
+						},
+					},
+				},
+				err: nil,
+			},
+		},
+		"ValidStringInterpolationPatch": {
+			reason: "Should interpolate a template of composite field paths and write the result to the composed resource",
+			args: args{
+				patch: Patch{
+					Type:        PatchTypeStringInterpolation,
+					Template:    pointer.StringPtr("${objectMeta.name}-${objectMeta.labels['Test']}"),
+					ToFieldPath: pointer.StringPtr("objectMeta.labels['Test']"),
+				},
+				cp: &fake.Composite{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "cp",
+						Labels: map[string]string{
+							"Test": "blah",
+						},
+					},
+					ConnectionDetailsLastPublishedTimer: lpt,
+				},
+				cd: &fake.Composed{
+					ObjectMeta: metav1.ObjectMeta{Name: "cd"},
+				},
+			},
+			want: want{
+				cd: &fake.Composed{
+					ObjectMeta: metav1.ObjectMeta{Name: "cd", Labels: map[string]string{
+						"Test": "cp-blah",
+					}},
+				},
+				err: nil,
+			},
+		},
+		"CombineToCompositeString": {
+			reason: "Should combine temporary values using string combine and write the result to the composite",
+			args: args{
+				patch: Patch{
+					Type:        PatchTypeCombineToComposite,
+					ToFieldPath: pointer.StringPtr("objectMeta.labels['Test']"),
+					Combine: Combine{
+						Type: CombineTypeString,
+						String: &StringCombine{
+							Format: "%s-%s",
+						},
+					},
+				},
+				tmp: &[]interface{}{
+					"test1",
+					"test2",
+				},
+				cp: &fake.Composite{
+					ConnectionDetailsLastPublishedTimer: lpt,
+				},
+				cd: &fake.Composed{ObjectMeta: metav1.ObjectMeta{Name: "cd"}},
+			},
+			want: want{
+				err: nil,
+				cp: &fake.Composite{
+					ObjectMeta: metav1.ObjectMeta{
+						Labels: map[string]string{
+							"Test": "test1-test2",
+						},
+					},
+					ConnectionDetailsLastPublishedTimer: lpt,
+				},
+			},
+		},
+		"CombineToCompositeMissingCombine": {
+			reason: "Should return an error rather than silently no-op when CombineToComposite has no Combine configured",
+			args: args{
+				patch: Patch{
+					Type:        PatchTypeCombineToComposite,
+					ToFieldPath: pointer.StringPtr("objectMeta.labels['Test']"),
+				},
+				cp: &fake.Composite{
+					ConnectionDetailsLastPublishedTimer: lpt,
+				},
+				cd: &fake.Composed{ObjectMeta: metav1.ObjectMeta{Name: "cd"}},
+			},
+			want: want{
+				err: errors.New(errFmtCombineConfigMissing),
+			},
+		},
 	}
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
@@ -777,3 +1301,391 @@ func TestPatchApply(t *testing.T) {
 		})
 	}
 }
+
+func TestResolveTemplate(t *testing.T) {
+	type args struct {
+		tpl  string
+		data map[string]interface{}
+	}
+	type want struct {
+		o         string
+		err       error
+		wantError bool
+	}
+
+	cases := map[string]struct {
+		reason string
+		args
+		want
+	}{
+		"NoPlaceholders": {
+			reason: "A template with no placeholders should be returned unchanged",
+			args: args{
+				tpl: "no placeholders here",
+			},
+			want: want{
+				o: "no placeholders here",
+			},
+		},
+		"SingleField": {
+			reason: "Should substitute a single placeholder with its resolved value",
+			args: args{
+				tpl: "${spec.parameters.size}",
+				data: map[string]interface{}{
+					"spec": map[string]interface{}{
+						"parameters": map[string]interface{}{
+							"size": "large",
+						},
+					},
+				},
+			},
+			want: want{
+				o: "large",
+			},
+		},
+		"MultipleFieldsAndLiterals": {
+			reason: "Should combine literal text with multiple resolved placeholders",
+			args: args{
+				tpl: "${metadata.labels.region}-${metadata.labels.zone}",
+				data: map[string]interface{}{
+					"metadata": map[string]interface{}{
+						"labels": map[string]interface{}{
+							"region": "us-east",
+							"zone":   "1a",
+						},
+					},
+				},
+			},
+			want: want{
+				o: "us-east-1a",
+			},
+		},
+		"EscapedDollar": {
+			reason: "A doubled $$ should be substituted with a literal $",
+			args: args{
+				tpl: "$$${spec.parameters.size}",
+				data: map[string]interface{}{
+					"spec": map[string]interface{}{
+						"parameters": map[string]interface{}{
+							"size": "large",
+						},
+					},
+				},
+			},
+			want: want{
+				o: "$large",
+			},
+		},
+		"NestedSliceIndex": {
+			reason: "Should resolve a placeholder that indexes into a nested slice",
+			args: args{
+				tpl: "${spec.things[0].name}",
+				data: map[string]interface{}{
+					"spec": map[string]interface{}{
+						"things": []interface{}{
+							map[string]interface{}{"name": "widget"},
+						},
+					},
+				},
+			},
+			want: want{
+				o: "widget",
+			},
+		},
+		"MissingFieldPath": {
+			reason: "Should return an error when a placeholder's field path cannot be resolved",
+			args: args{
+				tpl:  "${spec.parameters.missing}",
+				data: map[string]interface{}{},
+			},
+			want: want{
+				wantError: true,
+			},
+		},
+		"UnterminatedPlaceholder": {
+			reason: "Should return an error when a placeholder is never closed",
+			args: args{
+				tpl: "${spec.parameters.size",
+			},
+			want: want{
+				err: errors.New(errTemplateUnterminatedPlaceholder),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := resolveTemplate(tc.args.tpl, fieldpath.Pave(tc.args.data))
+
+			if diff := cmp.Diff(tc.want.o, got); diff != "" {
+				t.Errorf("\n%s\nresolveTemplate(...): -want, +got:\n%s", tc.reason, diff)
+			}
+			if tc.want.wantError {
+				if err == nil {
+					t.Errorf("\n%s\nresolveTemplate(...): wanted an error, got none", tc.reason)
+				}
+				return
+			}
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nresolveTemplate(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestCompositionSpecValidate(t *testing.T) {
+	replace := MergePolicyReplace
+	appendPolicy := MergePolicyAppend
+
+	cases := map[string]struct {
+		reason string
+		spec   CompositionSpec
+		want   []Conflict
+	}{
+		"NoPatches": {
+			reason: "Should return no conflicts for a Composition with no patches",
+			spec: CompositionSpec{
+				Resources: []ComposedTemplate{{}},
+			},
+			want: nil,
+		},
+		"DuplicateWriteReplace": {
+			reason: "Should flag two patches that write to the same path with the default Replace policy",
+			spec: CompositionSpec{
+				Resources: []ComposedTemplate{{
+					Patches: []Patch{
+						{
+							Type:          PatchTypeFromCompositeFieldPath,
+							FromFieldPath: pointer.StringPtr("spec.a"),
+							ToFieldPath:   pointer.StringPtr("spec.target"),
+						},
+						{
+							Type:          PatchTypeFromCompositeFieldPath,
+							FromFieldPath: pointer.StringPtr("spec.b"),
+							ToFieldPath:   pointer.StringPtr("spec.target"),
+						},
+					},
+				}},
+			},
+			want: []Conflict{
+				{Type: ConflictTypeDuplicateWrite, Resource: 0, Path: "spec.target"},
+			},
+		},
+		"DuplicateWriteExplicitReplace": {
+			reason: "Should flag two patches that write to the same path when one explicitly sets the Replace policy",
+			spec: CompositionSpec{
+				Resources: []ComposedTemplate{{
+					Patches: []Patch{
+						{
+							Type:          PatchTypeFromCompositeFieldPath,
+							FromFieldPath: pointer.StringPtr("spec.a"),
+							ToFieldPath:   pointer.StringPtr("spec.target"),
+							Policy:        &PatchPolicy{MergePolicy: &replace},
+						},
+						{
+							Type:          PatchTypeFromCompositeFieldPath,
+							FromFieldPath: pointer.StringPtr("spec.b"),
+							ToFieldPath:   pointer.StringPtr("spec.target"),
+							Policy:        &PatchPolicy{MergePolicy: &replace},
+						},
+					},
+				}},
+			},
+			want: []Conflict{
+				{Type: ConflictTypeDuplicateWrite, Resource: 0, Path: "spec.target"},
+			},
+		},
+		"CompatibleMergePolicy": {
+			reason: "Should not flag two patches that both Append to the same path",
+			spec: CompositionSpec{
+				Resources: []ComposedTemplate{{
+					Patches: []Patch{
+						{
+							Type:          PatchTypeFromCompositeFieldPath,
+							FromFieldPath: pointer.StringPtr("spec.a"),
+							ToFieldPath:   pointer.StringPtr("spec.target"),
+							Policy:        &PatchPolicy{MergePolicy: &appendPolicy},
+						},
+						{
+							Type:          PatchTypeFromCompositeFieldPath,
+							FromFieldPath: pointer.StringPtr("spec.b"),
+							ToFieldPath:   pointer.StringPtr("spec.target"),
+							Policy:        &PatchPolicy{MergePolicy: &appendPolicy},
+						},
+					},
+				}},
+			},
+			want: nil,
+		},
+		"DifferentResourcesNotConflicting": {
+			reason: "Should not flag writes to the same path on two different composed resources",
+			spec: CompositionSpec{
+				Resources: []ComposedTemplate{
+					{Patches: []Patch{{
+						Type:          PatchTypeFromCompositeFieldPath,
+						FromFieldPath: pointer.StringPtr("spec.a"),
+						ToFieldPath:   pointer.StringPtr("spec.target"),
+					}}},
+					{Patches: []Patch{{
+						Type:          PatchTypeFromCompositeFieldPath,
+						FromFieldPath: pointer.StringPtr("spec.a"),
+						ToFieldPath:   pointer.StringPtr("spec.target"),
+					}}},
+				},
+			},
+			want: nil,
+		},
+		"ToCompositeSharesCompositeScope": {
+			reason: "Should flag writes from two different resources that both target the same composite field path",
+			spec: CompositionSpec{
+				Resources: []ComposedTemplate{
+					{Patches: []Patch{{
+						Type:          PatchTypeToCompositeFieldPath,
+						FromFieldPath: pointer.StringPtr("status.atProvider.endpoint"),
+						ToFieldPath:   pointer.StringPtr("status.endpoint"),
+					}}},
+					{Patches: []Patch{{
+						Type:          PatchTypeToCompositeFieldPath,
+						FromFieldPath: pointer.StringPtr("status.atProvider.address"),
+						ToFieldPath:   pointer.StringPtr("status.endpoint"),
+					}}},
+				},
+			},
+			want: []Conflict{
+				{Type: ConflictTypeDuplicateWrite, Resource: -1, Path: "status.endpoint"},
+			},
+		},
+		"CombineArityMismatch": {
+			reason: "Should flag a Combine patch fed fewer values than its format string expects",
+			spec: CompositionSpec{
+				Resources: []ComposedTemplate{{
+					Patches: []Patch{
+						{
+							Type:          PatchTypeFromCompositeFieldPath,
+							FromFieldPath: pointer.StringPtr("spec.a"),
+							ToFieldPath:   pointer.StringPtr(combinerIdentifierString),
+						},
+						{
+							Type:        PatchTypePatchSet,
+							ToFieldPath: pointer.StringPtr("spec.target"),
+							Combine: Combine{
+								Type: CombineTypeString,
+								String: &StringCombine{
+									Format: "%s-%s",
+								},
+							},
+						},
+					},
+				}},
+			},
+			want: []Conflict{
+				{Type: ConflictTypeCombineArity, Resource: 0, Path: "spec.target"},
+			},
+		},
+		"CombineArityMatches": {
+			reason: "Should not flag a Combine patch fed exactly as many values as its format string expects",
+			spec: CompositionSpec{
+				Resources: []ComposedTemplate{{
+					Patches: []Patch{
+						{
+							Type:          PatchTypeFromCompositeFieldPath,
+							FromFieldPath: pointer.StringPtr("spec.a"),
+							ToFieldPath:   pointer.StringPtr(combinerIdentifierString),
+						},
+						{
+							Type:          PatchTypeFromCompositeFieldPath,
+							FromFieldPath: pointer.StringPtr("spec.b"),
+							ToFieldPath:   pointer.StringPtr(combinerIdentifierString),
+						},
+						{
+							Type:        PatchTypePatchSet,
+							ToFieldPath: pointer.StringPtr("spec.target"),
+							Combine: Combine{
+								Type: CombineTypeString,
+								String: &StringCombine{
+									Format: "%s-%s",
+								},
+							},
+						},
+					},
+				}},
+			},
+			want: nil,
+		},
+		"FieldTypeMismatch": {
+			reason: "Should flag two patches that Convert to different types but write to the same path",
+			spec: CompositionSpec{
+				Resources: []ComposedTemplate{{
+					Patches: []Patch{
+						{
+							Type:          PatchTypeFromCompositeFieldPath,
+							FromFieldPath: pointer.StringPtr("spec.a"),
+							ToFieldPath:   pointer.StringPtr("spec.target"),
+							Policy:        &PatchPolicy{MergePolicy: &appendPolicy},
+							Transforms: []Transform{{
+								Type:    TransformTypeConvert,
+								Convert: &ConvertTransform{ToType: ConvertTransformTypeString},
+							}},
+						},
+						{
+							Type:          PatchTypeFromCompositeFieldPath,
+							FromFieldPath: pointer.StringPtr("spec.b"),
+							ToFieldPath:   pointer.StringPtr("spec.target"),
+							Policy:        &PatchPolicy{MergePolicy: &appendPolicy},
+							Transforms: []Transform{{
+								Type:    TransformTypeConvert,
+								Convert: &ConvertTransform{ToType: ConvertTransformTypeInt64},
+							}},
+						},
+					},
+				}},
+			},
+			want: []Conflict{
+				{Type: ConflictTypeFieldType, Resource: 0, Path: "spec.target"},
+			},
+		},
+		"UnresolvedTypeNotFlagged": {
+			reason: "Should not flag a patch with no Convert transform (an unresolved, Maybe type) against one that is known",
+			spec: CompositionSpec{
+				Resources: []ComposedTemplate{{
+					Patches: []Patch{
+						{
+							Type:          PatchTypeFromCompositeFieldPath,
+							FromFieldPath: pointer.StringPtr("spec.a"),
+							ToFieldPath:   pointer.StringPtr("spec.target"),
+							Policy:        &PatchPolicy{MergePolicy: &appendPolicy},
+						},
+						{
+							Type:          PatchTypeFromCompositeFieldPath,
+							FromFieldPath: pointer.StringPtr("spec.b"),
+							ToFieldPath:   pointer.StringPtr("spec.target"),
+							Policy:        &PatchPolicy{MergePolicy: &appendPolicy},
+							Transforms: []Transform{{
+								Type:    TransformTypeConvert,
+								Convert: &ConvertTransform{ToType: ConvertTransformTypeInt64},
+							}},
+						},
+					},
+				}},
+			},
+			want: nil,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := tc.spec.Validate()
+
+			sort.Slice(got, func(i, j int) bool {
+				if got[i].Path != got[j].Path {
+					return got[i].Path < got[j].Path
+				}
+				return got[i].Type < got[j].Type
+			})
+
+			if diff := cmp.Diff(tc.want, got, cmpopts.IgnoreFields(Conflict{}, "Message")); diff != "" {
+				t.Errorf("\n%s\nValidate(): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,361 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// A ConflictType categorises the kind of inconsistency a Conflict reports.
+type ConflictType string
+
+// Conflict types.
+const (
+	// ConflictTypeDuplicateWrite is raised when two or more patches with an
+	// incompatible MergePolicy write to the same field path.
+	ConflictTypeDuplicateWrite ConflictType = "DuplicateWrite"
+
+	// ConflictTypeCombineArity is raised when a Combine patch's format
+	// string expects a different number of values than it is fed by the
+	// patches preceding it.
+	ConflictTypeCombineArity ConflictType = "CombineArity"
+
+	// ConflictTypeFieldType is raised when two or more patches write values
+	// of statically known, differing types to the same field path.
+	ConflictTypeFieldType ConflictType = "FieldType"
+)
+
+// A Conflict is a single inconsistency found by CompositionSpec.Validate
+// while walking a Composition's inlined patches.
+type Conflict struct {
+	// Type of inconsistency this Conflict describes.
+	Type ConflictType
+
+	// Resource is the index into CompositionSpec.Resources that this
+	// Conflict concerns, or -1 if it concerns the composite resource
+	// rather than a particular composed resource.
+	Resource int
+
+	// Path is the field path on which the Conflict was detected.
+	Path string
+
+	// Message is a human readable description of the Conflict.
+	Message string
+}
+
+// String returns the Conflict's Message.
+func (c Conflict) String() string {
+	return c.Message
+}
+
+// fieldKind is a coarse, statically inferred value kind used to detect
+// FieldType conflicts. It deliberately collapses the ConvertTransform
+// numeric types into a single kind, since a Composition author who mixes
+// int and float64 at a path is making the same mistake as one who mixes
+// string and int.
+type fieldKind string
+
+const (
+	fieldKindUnknown fieldKind = ""
+	fieldKindString  fieldKind = "string"
+	fieldKindNumber  fieldKind = "number"
+	fieldKindBool    fieldKind = "bool"
+)
+
+// writeClass is the constraint-graph node that one or more patches assign
+// to: a field path on either the composite resource, or a particular
+// composed resource template.
+type writeClass struct {
+	// resource is the index into CompositionSpec.Resources the class
+	// belongs to, or -1 for the composite resource.
+	resource int
+	path     string
+}
+
+func (w writeClass) String() string {
+	if w.resource < 0 {
+		return "composite:" + w.path
+	}
+	return fmt.Sprintf("resources[%d]:%s", w.resource, w.path)
+}
+
+// writer records a single patch's contribution to a writeClass, kept so
+// that a DuplicateWrite Conflict can be built once every patch assigning
+// to the class has been seen.
+type writer struct {
+	class writeClass
+	patch *Patch
+	kind  fieldKind
+}
+
+// writeClasses groups every writer seen so far by the writeClass it
+// assigns to, and tracks the statically known fieldKind established for
+// each class so a later, differing assignment can be reported as a
+// FieldType Conflict. A class whose kind is unknown (fieldKindUnknown,
+// i.e. "Maybe") is never used to reject a later assignment.
+type writeClasses struct {
+	kind    map[string]fieldKind
+	writers map[string][]writer
+}
+
+func newWriteClasses() *writeClasses {
+	return &writeClasses{
+		kind:    map[string]fieldKind{},
+		writers: map[string][]writer{},
+	}
+}
+
+// assign records that p writes value of kind k to class, returning a
+// FieldType Conflict if k is known and conflicts with a kind already
+// established for class.
+func (wc *writeClasses) assign(class writeClass, p *Patch, k fieldKind) *Conflict {
+	id := class.String()
+	wc.writers[id] = append(wc.writers[id], writer{class: class, patch: p, kind: k})
+
+	existing := wc.kind[id]
+	if k == fieldKindUnknown {
+		return nil
+	}
+	if existing == fieldKindUnknown {
+		wc.kind[id] = k
+		return nil
+	}
+	if existing != k {
+		return &Conflict{
+			Type:     ConflictTypeFieldType,
+			Resource: class.resource,
+			Path:     class.path,
+			Message:  fmt.Sprintf("patches targeting %s disagree on value type: %s and %s", class, existing, k),
+		}
+	}
+	return nil
+}
+
+// Validate builds a constraint graph over every patch in c.Resources and
+// runs a lightweight solver across it, returning every Conflict it can
+// detect between patches that write to the same field path: incompatible
+// writers that would clobber one another's value (DuplicateWrite),
+// Combine patches fed the wrong number of values (CombineArity), and
+// writers whose statically known output types disagree (FieldType).
+//
+// Validate should be called after InlinePatchSets, so that it sees a flat
+// list of Patches per resource rather than unresolved PatchSet references.
+// It does not attempt to resolve a patch's FromFieldPath against any
+// runtime composite or composed resource, so a patch whose output type
+// can't be determined statically (i.e. it has no Convert or String
+// transform, and is neither a StringInterpolation nor a string Combine
+// patch) is treated as Maybe and never causes a FieldType Conflict.
+func (c *CompositionSpec) Validate() []Conflict {
+	var conflicts []Conflict
+	wc := newWriteClasses()
+
+	for i, r := range c.Resources {
+		conflicts = append(conflicts, validateCombineArity(i, r.Patches)...)
+
+		for _, p := range r.Patches {
+			p := p
+			class, ok := writeClassOf(i, &p)
+			if !ok {
+				continue
+			}
+
+			if conf := wc.assign(class, &p, inferFieldKind(&p)); conf != nil {
+				conflicts = append(conflicts, *conf)
+			}
+		}
+	}
+
+	conflicts = append(conflicts, validateDuplicateWrites(wc)...)
+
+	return conflicts
+}
+
+// writeClassOf returns the writeClass that p assigns to, and false if p
+// does not write directly to a composite or composed resource field (e.g.
+// it feeds a combine buffer, or stashes its value in tmp).
+func writeClassOf(resource int, p *Patch) (writeClass, bool) {
+	switch p.Type {
+	case PatchTypePatchSet:
+		if p.Combine.Type == "" || p.ToFieldPath == nil || *p.ToFieldPath == combinerIdentifierString {
+			return writeClass{}, false
+		}
+		return writeClass{resource: resource, path: *p.ToFieldPath}, true
+
+	case PatchTypeCombineToComposite:
+		if p.Combine.Type == "" || p.ToFieldPath == nil || *p.ToFieldPath == combinerIdentifierString {
+			return writeClass{}, false
+		}
+		return writeClass{resource: -1, path: *p.ToFieldPath}, true
+
+	case PatchTypeToCompositeFieldPath:
+		path := pathOrDefault(p)
+		if path == "" || path == combinerIdentifierString {
+			return writeClass{}, false
+		}
+		return writeClass{resource: -1, path: path}, true
+
+	case PatchTypeFromCompositeFieldPath, PatchTypeStringInterpolation:
+		path := pathOrDefault(p)
+		if path == "" || path == combinerIdentifierString {
+			return writeClass{}, false
+		}
+		return writeClass{resource: resource, path: path}, true
+	}
+
+	return writeClass{}, false
+}
+
+// pathOrDefault returns p's ToFieldPath, defaulting to FromFieldPath when
+// ToFieldPath is unset, mirroring the default applied by
+// applyFromCompositeFieldPatch and applyToCompositeFieldPatch.
+func pathOrDefault(p *Patch) string {
+	if p.ToFieldPath != nil {
+		return *p.ToFieldPath
+	}
+	if p.FromFieldPath != nil {
+		return *p.FromFieldPath
+	}
+	return ""
+}
+
+// inferFieldKind returns the statically known fieldKind produced by p, or
+// fieldKindUnknown ("Maybe") if it can't be determined without resolving
+// p against a runtime resource.
+func inferFieldKind(p *Patch) fieldKind {
+	k := fieldKindUnknown
+
+	if p.Combine.Type == CombineTypeString {
+		return fieldKindString
+	}
+
+	if p.Type == PatchTypeStringInterpolation {
+		k = fieldKindString
+	}
+
+	for _, t := range p.Transforms {
+		switch t.Type {
+		case TransformTypeString:
+			k = fieldKindString
+		case TransformTypeConvert:
+			if t.Convert == nil {
+				continue
+			}
+			switch t.Convert.ToType {
+			case ConvertTransformTypeString:
+				k = fieldKindString
+			case ConvertTransformTypeInt, ConvertTransformTypeInt64, ConvertTransformTypeFloat64:
+				k = fieldKindNumber
+			case ConvertTransformTypeBool:
+				k = fieldKindBool
+			}
+		}
+	}
+
+	return k
+}
+
+// validateCombineArity returns a CombineArity Conflict for every Combine
+// patch in patches whose Combine.String.Format expects a different number
+// of values than it is fed by the run of patches immediately preceding it
+// that feed the combine buffer (i.e. those InlinePatchSets rewired to the
+// combinerIdentifierString sentinel).
+func validateCombineArity(resource int, patches []Patch) []Conflict {
+	var conflicts []Conflict
+
+	fed := 0
+	for _, p := range patches {
+		if p.ToFieldPath != nil && *p.ToFieldPath == combinerIdentifierString {
+			fed++
+			continue
+		}
+
+		if p.Combine.Type != CombineTypeString || p.Combine.String == nil {
+			fed = 0
+			continue
+		}
+
+		want := countFormatVerbs(p.Combine.String.Format)
+		if want != fed {
+			path := ""
+			if p.ToFieldPath != nil {
+				path = *p.ToFieldPath
+			}
+			conflicts = append(conflicts, Conflict{
+				Type:     ConflictTypeCombineArity,
+				Resource: resource,
+				Path:     path,
+				Message:  fmt.Sprintf("combine format %q expects %d value(s) but is fed %d", p.Combine.String.Format, want, fed),
+			})
+		}
+		fed = 0
+	}
+
+	return conflicts
+}
+
+// countFormatVerbs returns the number of fmt verbs in format, treating a
+// literal '%%' as zero verbs.
+func countFormatVerbs(format string) int {
+	return strings.Count(format, "%") - 2*strings.Count(format, "%%")
+}
+
+// validateDuplicateWrites returns a DuplicateWrite Conflict for every
+// writeClass in wc that was assigned to by two or more patches whose
+// MergePolicy doesn't let them safely coexist: all writers must share the
+// same non-Replace MergePolicy (Append or MergeObjects) to accumulate
+// rather than clobber one another.
+func validateDuplicateWrites(wc *writeClasses) []Conflict {
+	var conflicts []Conflict
+
+	ids := make([]string, 0, len(wc.writers))
+	for id := range wc.writers {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		ws := wc.writers[id]
+		if len(ws) < 2 {
+			continue
+		}
+
+		policy := mergePolicyOf(ws[0].patch.Policy)
+		compatible := policy != nil && *policy != MergePolicyReplace
+		for _, w := range ws[1:] {
+			p := mergePolicyOf(w.patch.Policy)
+			if p == nil || policy == nil || *p != *policy {
+				compatible = false
+				break
+			}
+		}
+
+		if compatible {
+			continue
+		}
+
+		conflicts = append(conflicts, Conflict{
+			Type:     ConflictTypeDuplicateWrite,
+			Resource: ws[0].class.resource,
+			Path:     ws[0].class.path,
+			Message:  fmt.Sprintf("%d patches write to %s without a compatible merge policy", len(ws), id),
+		})
+	}
+
+	return conflicts
+}
@@ -0,0 +1,41 @@
+/*
+Copyright 2020 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// A PatchSetLibrary is a cluster-scoped collection of reusable PatchSets
+// that one or more Compositions can pull in via CompositionSpec.Include,
+// rather than each Composition having to define the same PatchSet itself.
+//
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster,categories=crossplane
+type PatchSetLibrary struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec PatchSetLibrarySpec `json:"spec"`
+}
+
+// PatchSetLibrarySpec specifies a collection of reusable PatchSets.
+type PatchSetLibrarySpec struct {
+	// PatchSets define a named set of patches that may be pulled into a
+	// Composition's own PatchSets via CompositionSpec.Include.
+	PatchSets []PatchSet `json:"patchSets"`
+}